@@ -0,0 +1,167 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// StockQuery identifies a single /getStock call within a batch.
+type StockQuery struct {
+	Symbol string
+	Date   string
+	Hour   int
+}
+
+// StockResult is the outcome of one StockQuery within a QueryBatch call.
+type StockResult struct {
+	Query    StockQuery
+	Response *StockResponse
+	Err      error
+}
+
+func (q StockQuery) dedupKey() string {
+	return fmt.Sprintf("%s|%s|%d", q.Symbol, q.Date, q.Hour)
+}
+
+// rateLimiter spaces out calls to at most one per interval, providing the
+// same effect as golang.org/x/time/rate.NewLimiter(rps, 1).Wait. It's
+// hand-rolled rather than imported because this tree has no dependency
+// manifest to pin an external module against.
+type rateLimiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     time.Time
+}
+
+func newRateLimiter(rps float64) *rateLimiter {
+	if rps <= 0 {
+		return nil
+	}
+	return &rateLimiter{interval: time.Duration(float64(time.Second) / rps)}
+}
+
+// wait blocks until the next slot is free or ctx is done.
+func (r *rateLimiter) wait(ctx context.Context) error {
+	if r == nil {
+		return nil
+	}
+
+	r.mu.Lock()
+	now := time.Now()
+	if r.next.IsZero() || now.After(r.next) {
+		r.next = now.Add(r.interval)
+		r.mu.Unlock()
+		return nil
+	}
+	wait := r.next.Sub(now)
+	r.next = r.next.Add(r.interval)
+	r.mu.Unlock()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(wait):
+		return nil
+	}
+}
+
+// QueryBatch dispatches requests across a pool of concurrency worker
+// goroutines, preserving input ordering in the returned slice. Repeated
+// entries within the batch that share the same (symbol, date, hour) collapse
+// to a single HTTP call whose result is broadcast to every waiter. If
+// c.FailFast is set, the first fatal error cancels all in-flight workers and
+// is returned alongside the partial results gathered so far. If c.RPS is
+// set, calls are throttled to that rate so the shared x-api-key doesn't get
+// throttled server-side.
+func (c *StockClient) QueryBatch(ctx context.Context, requests []StockQuery, concurrency int) ([]StockResult, error) {
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	limiter := newRateLimiter(c.RPS)
+
+	results := make([]StockResult, len(requests))
+
+	type dedupEntry struct {
+		done chan struct{}
+		res  StockResult
+	}
+	var dedupMu sync.Mutex
+	dedup := make(map[string]*dedupEntry)
+
+	type job struct {
+		index int
+		query StockQuery
+	}
+
+	jobs := make(chan job, len(requests))
+	var fatalErr error
+	var fatalOnce sync.Once
+
+	var wg sync.WaitGroup
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				select {
+				case <-ctx.Done():
+					results[j.index] = StockResult{Query: j.query, Err: ctx.Err()}
+					continue
+				default:
+				}
+
+				key := j.query.dedupKey()
+
+				dedupMu.Lock()
+				entry, exists := dedup[key]
+				if !exists {
+					entry = &dedupEntry{done: make(chan struct{})}
+					dedup[key] = entry
+				}
+				dedupMu.Unlock()
+
+				if exists {
+					<-entry.done
+					results[j.index] = entry.res
+					continue
+				}
+
+				res := c.runBatchQuery(ctx, limiter, j.query)
+				entry.res = res
+				close(entry.done)
+				results[j.index] = res
+
+				if res.Err != nil && c.FailFast {
+					fatalOnce.Do(func() {
+						fatalErr = res.Err
+						cancel()
+					})
+				}
+			}
+		}()
+	}
+
+	for i, req := range requests {
+		jobs <- job{index: i, query: req}
+	}
+	close(jobs)
+
+	wg.Wait()
+
+	return results, fatalErr
+}
+
+func (c *StockClient) runBatchQuery(ctx context.Context, limiter *rateLimiter, query StockQuery) StockResult {
+	if err := limiter.wait(ctx); err != nil {
+		return StockResult{Query: query, Err: err}
+	}
+
+	resp, err := c.QueryStockData(ctx, query.Symbol, query.Date, query.Hour)
+	return StockResult{Query: query, Response: resp, Err: err}
+}
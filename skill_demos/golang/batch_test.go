@@ -0,0 +1,139 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func newGetStockServer(t *testing.T, onRequest func(symbol, date string)) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		symbol := r.URL.Query().Get("symbol")
+		date := r.URL.Query().Get("date")
+
+		onRequest(symbol, date)
+
+		json.NewEncoder(w).Encode(StockResponse{Symbol: symbol, Timestamp: date, Data: map[string]string{"close": "1"}})
+	}))
+}
+
+func TestQueryBatchDedupesRepeatedQueries(t *testing.T) {
+	var mu sync.Mutex
+	calls := map[string]int{}
+
+	server := newGetStockServer(t, func(symbol, date string) {
+		mu.Lock()
+		calls[symbol]++
+		mu.Unlock()
+	})
+	defer server.Close()
+
+	client := NewStockClient(server.URL, "key")
+
+	requests := []StockQuery{
+		{Symbol: "FB", Date: "2026-07-27", Hour: 0},
+		{Symbol: "FB", Date: "2026-07-27", Hour: 0},
+		{Symbol: "FB", Date: "2026-07-27", Hour: 0},
+		{Symbol: "AMZN", Date: "2026-07-27", Hour: 0},
+	}
+
+	results, err := client.QueryBatch(context.Background(), requests, 4)
+	if err != nil {
+		t.Fatalf("QueryBatch returned error: %v", err)
+	}
+	if len(results) != len(requests) {
+		t.Fatalf("expected %d results, got %d", len(requests), len(results))
+	}
+
+	mu.Lock()
+	fbCalls := calls["FB"]
+	amznCalls := calls["AMZN"]
+	mu.Unlock()
+
+	if fbCalls != 1 {
+		t.Errorf("expected 1 upstream call for the 3 duplicate FB queries, got %d", fbCalls)
+	}
+	if amznCalls != 1 {
+		t.Errorf("expected 1 upstream call for AMZN, got %d", amznCalls)
+	}
+
+	for i, res := range results[:3] {
+		if res.Err != nil || res.Response == nil || res.Response.Symbol != "FB" {
+			t.Errorf("result[%d] = %+v, want a broadcast FB response", i, res)
+		}
+	}
+}
+
+func TestQueryBatchFailFastCancelsRemaining(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("symbol") == "BAD" {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ErrorResponse{Message: "no data"})
+			return
+		}
+		json.NewEncoder(w).Encode(StockResponse{Symbol: r.URL.Query().Get("symbol")})
+	}))
+	defer server.Close()
+
+	client := NewStockClient(server.URL, "key")
+	client.FailFast = true
+
+	requests := []StockQuery{
+		{Symbol: "BAD", Date: "2026-07-27", Hour: 0},
+		{Symbol: "GOOD1", Date: "2026-07-27", Hour: 0},
+		{Symbol: "GOOD2", Date: "2026-07-27", Hour: 0},
+	}
+
+	results, err := client.QueryBatch(context.Background(), requests, 1)
+	if err == nil {
+		t.Fatal("expected QueryBatch to return the fatal error, got nil")
+	}
+
+	if results[0].Err == nil {
+		t.Errorf("expected the BAD query to have an error, got %+v", results[0])
+	}
+	for i, res := range results[1:] {
+		if res.Err == nil {
+			t.Errorf("result[%d] = %+v, want the query after the failure to be canceled", i+1, res)
+		}
+	}
+}
+
+func TestQueryBatchRateLimitsRequests(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(StockResponse{Symbol: r.URL.Query().Get("symbol")})
+	}))
+	defer server.Close()
+
+	client := NewStockClient(server.URL, "key")
+	client.RPS = 10 // one call every 100ms
+
+	requests := []StockQuery{
+		{Symbol: "A", Date: "2026-07-27", Hour: 0},
+		{Symbol: "B", Date: "2026-07-27", Hour: 0},
+		{Symbol: "C", Date: "2026-07-27", Hour: 0},
+	}
+
+	start := time.Now()
+	results, err := client.QueryBatch(context.Background(), requests, 3)
+	elapsed := time.Since(start)
+
+	if err != nil {
+		t.Fatalf("QueryBatch returned error: %v", err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("expected 3 results, got %d", len(results))
+	}
+
+	// 3 calls at 10 RPS (100ms spacing) take at least ~200ms; give plenty of
+	// slack for CI jitter while still catching a limiter that doesn't throttle
+	// at all (which would finish in low single-digit ms).
+	if elapsed < 150*time.Millisecond {
+		t.Errorf("expected rate limiting to space out requests, elapsed only %v", elapsed)
+	}
+}
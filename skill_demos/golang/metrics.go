@@ -0,0 +1,167 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Sample is one observation parsed out of a Prometheus text exposition
+// response, e.g. `stock_price{symbol="AMZN",field="close"} 123.45 1700000000000`.
+//
+// STATUS: only the client half of this request (this file) is implemented.
+// This repo only contains the client side of the stock service, so the
+// /metrics endpoint itself (HELP/TYPE headers, the stock_samples_queried_total
+// counter, etc.) is not here and has not been added anywhere. Do not treat
+// the backlog item this came from as closed until the service-repo owner
+// confirms the server-side half is tracked there.
+type Sample struct {
+	Name   string
+	Labels map[string]string
+	Value  float64
+	TS     int64 // unix millis, 0 if the line didn't include one
+}
+
+// ScrapeMetrics GETs /metrics and parses the Prometheus exposition format
+// into a slice of Sample, skipping comment (`#`) and blank lines.
+func (c *StockClient) ScrapeMetrics(ctx context.Context) ([]Sample, error) {
+	body, err := c.doGet(ctx, "/metrics", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseExposition(string(body))
+}
+
+// parseExposition parses lines of the form:
+//
+//	metric_name{label="value",...} value [timestamp]
+func parseExposition(text string) ([]Sample, error) {
+	var samples []Sample
+
+	scanner := bufio.NewScanner(strings.NewReader(text))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		sample, err := parseExpositionLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing metrics line %q: %v", line, err)
+		}
+		samples = append(samples, sample)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error scanning metrics body: %v", err)
+	}
+
+	return samples, nil
+}
+
+func parseExpositionLine(line string) (Sample, error) {
+	name := line
+	labels := map[string]string{}
+	rest := ""
+
+	if idx := strings.IndexByte(line, '{'); idx >= 0 {
+		name = line[:idx]
+		end := strings.IndexByte(line[idx:], '}')
+		if end < 0 {
+			return Sample{}, fmt.Errorf("unterminated label set")
+		}
+		end += idx
+
+		var err error
+		labels, err = parseLabels(line[idx+1 : end])
+		if err != nil {
+			return Sample{}, err
+		}
+		rest = strings.TrimSpace(line[end+1:])
+	} else if idx := strings.IndexByte(line, ' '); idx >= 0 {
+		name = line[:idx]
+		rest = strings.TrimSpace(line[idx:])
+	}
+
+	fields := strings.Fields(rest)
+	if len(fields) == 0 {
+		return Sample{}, fmt.Errorf("missing value")
+	}
+
+	value, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return Sample{}, fmt.Errorf("invalid value %q: %v", fields[0], err)
+	}
+
+	var ts int64
+	if len(fields) > 1 {
+		ts, err = strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			return Sample{}, fmt.Errorf("invalid timestamp %q: %v", fields[1], err)
+		}
+	}
+
+	return Sample{Name: strings.TrimSpace(name), Labels: labels, Value: value, TS: ts}, nil
+}
+
+func parseLabels(raw string) (map[string]string, error) {
+	labels := map[string]string{}
+	if strings.TrimSpace(raw) == "" {
+		return labels, nil
+	}
+
+	for _, pair := range splitLabelPairs(raw) {
+		eq := strings.IndexByte(pair, '=')
+		if eq < 0 {
+			return nil, fmt.Errorf("malformed label %q", pair)
+		}
+		key := strings.TrimSpace(pair[:eq])
+		val := strings.TrimSpace(pair[eq+1:])
+		val = strings.Trim(val, `"`)
+		labels[key] = val
+	}
+
+	return labels, nil
+}
+
+// splitLabelPairs splits a `key="value",key2="value2"` label body on commas
+// that fall outside quoted values.
+func splitLabelPairs(raw string) []string {
+	var pairs []string
+	var inQuotes bool
+	start := 0
+
+	for i, r := range raw {
+		switch r {
+		case '"':
+			inQuotes = !inQuotes
+		case ',':
+			if !inQuotes {
+				pairs = append(pairs, raw[start:i])
+				start = i + 1
+			}
+		}
+	}
+	pairs = append(pairs, raw[start:])
+
+	return pairs
+}
+
+// scrapeMetrics mirrors the free-function helper style used elsewhere in
+// this file for one-off CLI invocations: GET /metrics and print a summary.
+func scrapeMetrics(baseURL string, apiKey string) error {
+	client := NewStockClient(baseURL, apiKey)
+	samples, err := client.ScrapeMetrics(context.Background())
+	if err != nil {
+		return fmt.Errorf("error scraping metrics: %v", err)
+	}
+
+	fmt.Printf("Scraped %d samples\n", len(samples))
+	for _, sample := range samples {
+		fmt.Printf("%s%v = %g\n", sample.Name, sample.Labels, sample.Value)
+	}
+
+	return nil
+}
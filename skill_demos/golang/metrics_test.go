@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestParseExposition(t *testing.T) {
+	body := `# HELP stock_price last observed price
+# TYPE stock_price gauge
+stock_price{symbol="AMZN",field="close"} 123.45 1700000000000
+stock_samples_queried_total 42
+`
+	samples, err := parseExposition(body)
+	if err != nil {
+		t.Fatalf("parseExposition returned error: %v", err)
+	}
+	if len(samples) != 2 {
+		t.Fatalf("expected 2 samples, got %d", len(samples))
+	}
+
+	price := samples[0]
+	if price.Name != "stock_price" {
+		t.Errorf("expected name stock_price, got %q", price.Name)
+	}
+	if price.Labels["symbol"] != "AMZN" || price.Labels["field"] != "close" {
+		t.Errorf("unexpected labels: %+v", price.Labels)
+	}
+	if price.Value != 123.45 {
+		t.Errorf("expected value 123.45, got %v", price.Value)
+	}
+	if price.TS != 1700000000000 {
+		t.Errorf("expected ts 1700000000000, got %d", price.TS)
+	}
+
+	total := samples[1]
+	if total.Name != "stock_samples_queried_total" || len(total.Labels) != 0 || total.Value != 42 {
+		t.Errorf("unexpected counter sample: %+v", total)
+	}
+}
+
+func TestScrapeMetrics(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/metrics" {
+			t.Errorf("expected request to /metrics, got %s", r.URL.Path)
+		}
+		if r.Header.Get("x-api-key") != "test-key" {
+			t.Errorf("expected x-api-key header, got %q", r.Header.Get("x-api-key"))
+		}
+		w.Write([]byte(`stock_price{symbol="FB",field="close"} 10 5
+`))
+	}))
+	defer server.Close()
+
+	client := NewStockClient(server.URL, "test-key")
+	samples, err := client.ScrapeMetrics(context.Background())
+	if err != nil {
+		t.Fatalf("ScrapeMetrics returned error: %v", err)
+	}
+	if len(samples) != 1 || samples[0].Name != "stock_price" {
+		t.Fatalf("unexpected samples: %+v", samples)
+	}
+}
@@ -0,0 +1,254 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"sort"
+	"time"
+)
+
+// RetryPolicy controls how StockClient retries idempotent GETs that fail
+// with a 5xx, a 429, or a network error.
+type RetryPolicy struct {
+	MaxAttempts int           // total attempts including the first try
+	BaseDelay   time.Duration // backoff base; doubles each retry
+	MaxDelay    time.Duration // backoff cap
+}
+
+// DefaultRetryPolicy matches the base/cap/attempts used across the rest of
+// this client: 100ms base, 5s cap, 4 attempts total.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 4,
+	BaseDelay:   100 * time.Millisecond,
+	MaxDelay:    5 * time.Second,
+}
+
+// APIError wraps a non-2xx response so callers can branch on status or on
+// the decoded ErrorResponse via errors.As.
+type APIError struct {
+	StatusCode int
+	Body       ErrorResponse
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("request failed with status %d: %s", e.StatusCode, e.Body.Message)
+}
+
+// StockClient is a context-aware client for the stock service, safe to embed
+// in long-running services. Zero value is not usable; use NewStockClient.
+type StockClient struct {
+	BaseURL     string
+	APIKey      string
+	HTTPClient  *http.Client
+	Deadline    time.Duration // overall deadline across all attempts
+	TryTimeout  time.Duration // per-attempt timeout
+	RetryPolicy RetryPolicy
+	FailFast    bool    // cancel in-flight QueryBatch workers on first fatal error
+	RPS         float64 // QueryBatch rate limit; 0 disables throttling
+}
+
+// NewStockClient builds a StockClient with sane defaults: a 30s overall
+// deadline, a 10s per-try timeout, and DefaultRetryPolicy.
+func NewStockClient(baseURL string, apiKey string) *StockClient {
+	return &StockClient{
+		BaseURL:     baseURL,
+		APIKey:      apiKey,
+		HTTPClient:  &http.Client{},
+		Deadline:    30 * time.Second,
+		TryTimeout:  10 * time.Second,
+		RetryPolicy: DefaultRetryPolicy,
+	}
+}
+
+// doGet issues a GET against path with the given query values, retrying on
+// 5xx/429/network errors with exponential backoff and full jitter. The
+// returned body is only populated on a 200; non-2xx responses are returned
+// as *APIError.
+func (c *StockClient) doGet(ctx context.Context, path string, query url.Values) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.Deadline)
+	defer cancel()
+
+	reqURL, err := url.Parse(fmt.Sprintf("%s%s", c.BaseURL, path))
+	if err != nil {
+		return nil, fmt.Errorf("error parsing base URL: %v", err)
+	}
+	reqURL.RawQuery = query.Encode()
+
+	var lastErr error
+	for attempt := 0; attempt < c.RetryPolicy.MaxAttempts; attempt++ {
+		if attempt > 0 {
+			delay := backoffWithFullJitter(c.RetryPolicy, attempt)
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(delay):
+			}
+		}
+
+		body, apiErr, err := c.tryOnce(ctx, reqURL.String())
+		if err == nil && apiErr == nil {
+			return body, nil
+		}
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		// apiErr != nil: only 5xx and 429 are worth retrying.
+		if apiErr.StatusCode == http.StatusTooManyRequests || apiErr.StatusCode >= 500 {
+			lastErr = apiErr
+			continue
+		}
+		return nil, apiErr
+	}
+
+	return nil, lastErr
+}
+
+func (c *StockClient) tryOnce(ctx context.Context, reqURL string) ([]byte, *APIError, error) {
+	tryCtx, cancel := context.WithTimeout(ctx, c.TryTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(tryCtx, "GET", reqURL, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error creating request: %v", err)
+	}
+	req.Header.Set("x-api-key", c.APIKey)
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error making request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error reading response body: %v", err)
+	}
+
+	if resp.StatusCode != 200 {
+		var errorResp ErrorResponse
+		_ = json.Unmarshal(body, &errorResp)
+		return nil, &APIError{StatusCode: resp.StatusCode, Body: errorResp}, nil
+	}
+
+	return body, nil, nil
+}
+
+// backoffWithFullJitter returns a random delay in [0, min(cap, base*2^attempt)).
+func backoffWithFullJitter(policy RetryPolicy, attempt int) time.Duration {
+	backoff := policy.BaseDelay << uint(attempt-1)
+	if backoff > policy.MaxDelay || backoff <= 0 {
+		backoff = policy.MaxDelay
+	}
+	return time.Duration(rand.Int63n(int64(backoff)))
+}
+
+// GetAvailableSymbols fetches the list of symbols the service has data for.
+func (c *StockClient) GetAvailableSymbols(ctx context.Context) ([]string, error) {
+	body, err := c.doGet(ctx, "/availableSymbols", url.Values{})
+	if err != nil {
+		return nil, err
+	}
+
+	var symbolsResp SymbolsResponse
+	if err := json.Unmarshal(body, &symbolsResp); err != nil {
+		return nil, fmt.Errorf("error decoding JSON: %v", err)
+	}
+
+	return symbolsResp.AvailableSymbols, nil
+}
+
+// QueryStockData fetches a single hour of data for symbol.
+func (c *StockClient) QueryStockData(ctx context.Context, symbol string, date string, hour int) (*StockResponse, error) {
+	query := url.Values{}
+	query.Set("symbol", symbol)
+	query.Set("date", date)
+	query.Set("hour", fmt.Sprintf("%d", hour))
+
+	body, err := c.doGet(ctx, "/getStock", query)
+	if err != nil {
+		return nil, err
+	}
+
+	var stockResp StockResponse
+	if err := json.Unmarshal(body, &stockResp); err != nil {
+		return nil, fmt.Errorf("error decoding JSON: %v", err)
+	}
+
+	return &stockResp, nil
+}
+
+// QueryStockRange hits /getStockRange, the batched counterpart to /getStock,
+// for pulling a backfill in one request instead of querying hour by hour.
+// start and end are RFC3339 timestamps and step is a duration string like
+// "1h" or "15m", mirroring the Prometheus query_range API shape. Samples in
+// the response are sorted by Timestamp ascending.
+func (c *StockClient) QueryStockRange(ctx context.Context, symbol string, start string, end string, step string) (*StockRangeResponse, error) {
+	startTime, err := time.Parse(time.RFC3339, start)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing start: %v", err)
+	}
+
+	endTime, err := time.Parse(time.RFC3339, end)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing end: %v", err)
+	}
+
+	if !endTime.After(startTime) {
+		return nil, fmt.Errorf("end %s must be after start %s", end, start)
+	}
+
+	stepDuration, err := time.ParseDuration(step)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing step: %v", err)
+	}
+
+	if stepDuration <= 0 {
+		return nil, fmt.Errorf("step must be positive, got %s", step)
+	}
+
+	numPoints := int(endTime.Sub(startTime) / stepDuration)
+	if numPoints > maxPoints {
+		return nil, fmt.Errorf("requested range spans %d points, exceeds maxPoints (%d); narrow the range or increase step", numPoints, maxPoints)
+	}
+
+	query := url.Values{}
+	query.Set("symbol", symbol)
+	query.Set("start", start)
+	query.Set("end", end)
+	query.Set("step", step)
+
+	body, err := c.doGet(ctx, "/getStockRange", query)
+	if err != nil {
+		return nil, err
+	}
+
+	var rangeResp StockRangeResponse
+	if err := json.Unmarshal(body, &rangeResp); err != nil {
+		return nil, fmt.Errorf("error decoding JSON: %v", err)
+	}
+
+	sort.Slice(rangeResp.Samples, func(i, j int) bool {
+		return rangeResp.Samples[i].Timestamp < rangeResp.Samples[j].Timestamp
+	})
+
+	return &rangeResp, nil
+}
+
+// IsRetryableAPIError reports whether err is an *APIError the client would
+// itself have retried (5xx or 429), useful for callers composing their own
+// retry logic around non-GET calls.
+func IsRetryableAPIError(err error) bool {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	return apiErr.StatusCode == http.StatusTooManyRequests || apiErr.StatusCode >= 500
+}
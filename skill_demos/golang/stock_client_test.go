@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func testClient(baseURL string) *StockClient {
+	c := NewStockClient(baseURL, "key")
+	c.RetryPolicy = RetryPolicy{MaxAttempts: 4, BaseDelay: 5 * time.Millisecond, MaxDelay: 20 * time.Millisecond}
+	c.Deadline = time.Second
+	c.TryTimeout = time.Second
+	return c
+}
+
+func TestDoGetRetriesOn500ThenSucceeds(t *testing.T) {
+	var attempts int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Write([]byte(`{"available_symbols":["FB"]}`))
+	}))
+	defer server.Close()
+
+	client := testClient(server.URL)
+	symbols, err := client.GetAvailableSymbols(context.Background())
+	if err != nil {
+		t.Fatalf("GetAvailableSymbols returned error: %v", err)
+	}
+	if len(symbols) != 1 || symbols[0] != "FB" {
+		t.Errorf("unexpected symbols: %v", symbols)
+	}
+	if got := atomic.LoadInt32(&attempts); got != 2 {
+		t.Errorf("expected exactly 2 attempts (1 failure + 1 success), got %d", got)
+	}
+}
+
+func TestDoGetDoesNotRetryOn400(t *testing.T) {
+	var attempts int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"message":"bad request"}`))
+	}))
+	defer server.Close()
+
+	client := testClient(server.URL)
+	_, err := client.GetAvailableSymbols(context.Background())
+	if err == nil {
+		t.Fatal("expected an error for a 400 response, got nil")
+	}
+	if IsRetryableAPIError(err) {
+		t.Errorf("expected a 400 to be reported as non-retryable, got %v", err)
+	}
+	if got := atomic.LoadInt32(&attempts); got != 1 {
+		t.Errorf("expected exactly 1 attempt for a non-retryable 400, got %d", got)
+	}
+}
+
+func TestDoGetAbortsOnContextCancelDuringBackoff(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	client := testClient(server.URL)
+	client.RetryPolicy = RetryPolicy{MaxAttempts: 4, BaseDelay: 2 * time.Second, MaxDelay: 2 * time.Second}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := client.GetAvailableSymbols(ctx)
+	elapsed := time.Since(start)
+
+	if err == nil {
+		t.Fatal("expected an error once the context expires, got nil")
+	}
+	if elapsed > time.Second {
+		t.Errorf("expected doGet to abort mid-backoff near the 50ms deadline, took %v", elapsed)
+	}
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+// unreachableClient points at an address nothing listens on, so these tests
+// fail loudly if a case's validation doesn't short-circuit before any HTTP
+// call is made.
+func unreachableClient() *StockClient {
+	c := NewStockClient("http://127.0.0.1:1", "key")
+	c.Deadline = 200 * time.Millisecond
+	c.TryTimeout = 200 * time.Millisecond
+	c.RetryPolicy = RetryPolicy{MaxAttempts: 1, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond}
+	return c
+}
+
+func TestQueryStockRangeValidation(t *testing.T) {
+	base := time.Date(2026, 7, 27, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		start   string
+		end     string
+		step    string
+		wantErr string
+	}{
+		{
+			name:    "end equal to start",
+			start:   base.Format(time.RFC3339),
+			end:     base.Format(time.RFC3339),
+			step:    "1h",
+			wantErr: "must be after",
+		},
+		{
+			name:    "end before start",
+			start:   base.Format(time.RFC3339),
+			end:     base.Add(-time.Hour).Format(time.RFC3339),
+			step:    "1h",
+			wantErr: "must be after",
+		},
+		{
+			name:    "step not positive",
+			start:   base.Format(time.RFC3339),
+			end:     base.Add(time.Hour).Format(time.RFC3339),
+			step:    "0s",
+			wantErr: "must be positive",
+		},
+		{
+			name:    "range exceeds maxPoints",
+			start:   base.Format(time.RFC3339),
+			end:     base.Add(time.Duration(maxPoints+10) * time.Second).Format(time.RFC3339),
+			step:    "1s",
+			wantErr: "exceeds maxPoints",
+		},
+	}
+
+	client := unreachableClient()
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := client.QueryStockRange(context.Background(), "FB", tc.start, tc.end, tc.step)
+			if err == nil {
+				t.Fatalf("expected an error, got nil")
+			}
+			if !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("expected error to contain %q, got: %v", tc.wantErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// StreamAllData fetches /allData and decodes it token-by-token instead of
+// buffering the whole body, so the client doesn't OOM on realistic
+// datasets. handler is invoked once per top-level symbol with its nested
+// points map; the decoded value is discarded before moving to the next
+// symbol.
+//
+// Connection failures and 5xx/429 responses received before any streaming
+// begins are retried per c.RetryPolicy, same as doGet. Once the body starts
+// streaming, errors are returned as-is since there is no safe way to redo a
+// partially consumed response.
+func (c *StockClient) StreamAllData(ctx context.Context, handler func(symbol string, points map[string]string) error) error {
+	ctx, cancel := context.WithTimeout(ctx, c.Deadline)
+	defer cancel()
+
+	var resp *http.Response
+	var lastErr error
+
+	for attempt := 0; attempt < c.RetryPolicy.MaxAttempts; attempt++ {
+		if attempt > 0 {
+			delay := backoffWithFullJitter(c.RetryPolicy, attempt)
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(delay):
+			}
+		}
+
+		r, apiErr, err := c.openAllDataStream(ctx)
+		if err == nil && apiErr == nil {
+			resp = r
+			break
+		}
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if apiErr.StatusCode == http.StatusTooManyRequests || apiErr.StatusCode >= 500 {
+			lastErr = apiErr
+			continue
+		}
+		return apiErr
+	}
+
+	if resp == nil {
+		return lastErr
+	}
+	defer resp.Body.Close()
+
+	return decodeAllDataStream(resp.Body, handler)
+}
+
+// openAllDataStream issues a single GET to /allData and returns the live
+// response for streaming; it does not consume the body.
+func (c *StockClient) openAllDataStream(ctx context.Context) (*http.Response, *APIError, error) {
+	reqURL := fmt.Sprintf("%s/allData", c.BaseURL)
+
+	req, err := http.NewRequestWithContext(ctx, "GET", reqURL, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error creating request: %v", err)
+	}
+	req.Header.Set("x-api-key", c.APIKey)
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error making request: %v", err)
+	}
+
+	if resp.StatusCode != 200 {
+		defer resp.Body.Close()
+		var errorResp ErrorResponse
+		_ = json.NewDecoder(resp.Body).Decode(&errorResp)
+		return nil, &APIError{StatusCode: resp.StatusCode, Body: errorResp}, nil
+	}
+
+	return resp, nil, nil
+}
+
+// decodeAllDataStream walks the /allData object one symbol at a time. The
+// server also uses this same top-level object shape to report errors (a
+// "message" key, optionally followed by "reason"), so the first key is
+// inspected before assuming the rest of the object is stock data.
+func decodeAllDataStream(body io.Reader, handler func(symbol string, points map[string]string) error) error {
+	dec := json.NewDecoder(body)
+
+	if _, err := dec.Token(); err != nil {
+		return fmt.Errorf("error reading opening token at offset %d: %v", dec.InputOffset(), err)
+	}
+
+	symbolCount := 0
+	totalDataPoints := 0
+
+	for dec.More() {
+		keyTok, err := dec.Token()
+		if err != nil {
+			return fmt.Errorf("error reading key at offset %d: %v", dec.InputOffset(), err)
+		}
+
+		key, ok := keyTok.(string)
+		if !ok {
+			return fmt.Errorf("expected string key at offset %d, got %v", dec.InputOffset(), keyTok)
+		}
+
+		if key == "message" {
+			var message string
+			if err := dec.Decode(&message); err != nil {
+				return fmt.Errorf("error decoding message at offset %d: %v", dec.InputOffset(), err)
+			}
+
+			reason := ""
+			if dec.More() {
+				if _, err := dec.Token(); err != nil { // "reason" key
+					return fmt.Errorf("error reading reason key at offset %d: %v", dec.InputOffset(), err)
+				}
+				if err := dec.Decode(&reason); err != nil {
+					return fmt.Errorf("error decoding reason at offset %d: %v", dec.InputOffset(), err)
+				}
+			}
+
+			if reason != "" {
+				return fmt.Errorf("received message instead of data: %s (reason: %s)", message, reason)
+			}
+			return fmt.Errorf("received message instead of data: %s", message)
+		}
+
+		var points map[string]string
+		if err := dec.Decode(&points); err != nil {
+			return fmt.Errorf("error decoding symbol %q at offset %d: %v", key, dec.InputOffset(), err)
+		}
+
+		if err := handler(key, points); err != nil {
+			return fmt.Errorf("handler error for symbol %q: %v", key, err)
+		}
+
+		symbolCount++
+		totalDataPoints += len(points)
+	}
+
+	if _, err := dec.Token(); err != nil {
+		return fmt.Errorf("error reading closing token at offset %d: %v", dec.InputOffset(), err)
+	}
+
+	fmt.Printf("Streamed %d symbols, %d data points\n", symbolCount, totalDataPoints)
+
+	return nil
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestStreamAllDataHappyPath(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"FB":{"2026-07-27T00":"100"},"AMZN":{"2026-07-27T00":"200","2026-07-27T01":"201"}}`))
+	}))
+	defer server.Close()
+
+	client := testClient(server.URL)
+
+	got := map[string]int{}
+	err := client.StreamAllData(context.Background(), func(symbol string, points map[string]string) error {
+		got[symbol] = len(points)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("StreamAllData returned error: %v", err)
+	}
+
+	if got["FB"] != 1 || got["AMZN"] != 2 {
+		t.Errorf("unexpected per-symbol point counts: %+v", got)
+	}
+}
+
+func TestStreamAllDataErrorEnvelope(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"message":"no data for range","reason":"outside retention window"}`))
+	}))
+	defer server.Close()
+
+	client := testClient(server.URL)
+
+	err := client.StreamAllData(context.Background(), func(symbol string, points map[string]string) error {
+		t.Errorf("handler should not be called for an error envelope, got symbol %q", symbol)
+		return nil
+	})
+	if err == nil {
+		t.Fatal("expected an error for the message/reason envelope, got nil")
+	}
+	if !strings.Contains(err.Error(), "no data for range") || !strings.Contains(err.Error(), "outside retention window") {
+		t.Errorf("expected error to surface message and reason, got: %v", err)
+	}
+}
+
+func TestStreamAllDataTruncatedBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", "9999")
+		w.Write([]byte(`{"FB":{"2026-07-27T00":"100"`)) // truncated mid-object, never closed
+	}))
+	defer server.Close()
+
+	client := testClient(server.URL)
+
+	called := false
+	err := client.StreamAllData(context.Background(), func(symbol string, points map[string]string) error {
+		called = true
+		return nil
+	})
+	if err == nil {
+		t.Fatal("expected an error for a truncated body, got nil")
+	}
+	if called {
+		t.Error("handler should not be called for a symbol whose body never completes")
+	}
+}
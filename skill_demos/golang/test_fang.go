@@ -1,12 +1,11 @@
 package main
 
 import (
-	"encoding/json"
+	"context"
+	"errors"
 	"fmt"
-	"io/ioutil"
 	"math/rand"
-	"net/http"
-	"net/url"
+	"os"
 	"time"
 )
 
@@ -31,17 +30,45 @@ type ErrorResponse struct {
 	AvailableHours   []int    `json:"available_hours,omitempty"`
 }
 
+// StockSample is a single timestamped observation returned by /getStockRange.
+type StockSample struct {
+	Timestamp string            `json:"timestamp"`
+	Values    map[string]string `json:"values"`
+}
+
+// StockRangeResponse matches the JSON response from /getStockRange. Samples
+// are returned sorted by Timestamp ascending.
+type StockRangeResponse struct {
+	Symbol  string        `json:"symbol"`
+	Samples []StockSample `json:"samples"`
+}
+
+// maxPoints bounds how many samples a single /getStockRange call may request,
+// mirroring Prometheus' query_range point-count protection.
+const maxPoints = 11000
+
 func main() {
 	// 1) Adjust these as needed
 	apiKey := "8f4b9e7d1c6a2305f8e9d7b6c5a4e3f2d1c0b9a8f7e6d5c4b3a2918f7e6d5c4" // Must match SERVICE_API_KEY
 	baseURL := "http://localhost:8000/api"                                      // Updated to use the API prefix
 	fangSymbols := []string{"FB", "AMZN", "NFLX", "GOOG"}
 
+	// "metrics" subcommand: scrape /metrics instead of running the normal demo
+	if len(os.Args) > 1 && os.Args[1] == "metrics" {
+		if err := scrapeMetrics(baseURL, apiKey); err != nil {
+			fmt.Printf("Error scraping metrics: %v\n", err)
+		}
+		return
+	}
+
 	// 2) Seed random number generator
 	rand.Seed(time.Now().UnixNano())
 
+	client := NewStockClient(baseURL, apiKey)
+	ctx := context.Background()
+
 	// First, check which symbols have data in the database
-	availableSymbols, err := getAvailableSymbols(baseURL, apiKey)
+	availableSymbols, err := client.GetAvailableSymbols(ctx)
 	if err != nil {
 		fmt.Printf("Error fetching available symbols: %v\n", err)
 	} else {
@@ -53,196 +80,86 @@ func main() {
 		}
 	}
 
-	// 3) For each symbol, pick a random time in the last 72 hours
+	// 3) For each symbol, pick a random time in the last 72 hours and query
+	// them all together via a bounded worker pool instead of serially.
+	batch := make([]StockQuery, 0, len(fangSymbols))
 	for _, symbol := range fangSymbols {
-		// randomHours in [0..72)
 		randomHours := rand.Intn(72)
-
-		// Now minus randomHours
 		randomTime := time.Now().UTC().Add(-time.Duration(randomHours) * time.Hour)
 
-		dateParam := randomTime.Format("2006-01-02")
-		hourParam := randomTime.Hour()
-
-		// 4) Make the request to get stock data
-		fmt.Printf("=== %s Query ===\n", symbol)
-		fmt.Printf("Random time: %s %02d:00:00\n", dateParam, hourParam)
-
-		err := queryStockData(baseURL, apiKey, symbol, dateParam, hourParam)
-		if err != nil {
-			fmt.Printf("Error querying stock data: %v\n\n", err)
-		}
-	}
-
-	// 5) Also test the /allData endpoint
-	fmt.Println("\n=== Testing /allData endpoint ===")
-	err = queryAllData(baseURL, apiKey)
-	if err != nil {
-		fmt.Printf("Error querying all data: %v\n", err)
-	}
-
-	fmt.Println("\nAll queries complete.")
-}
-
-// Helper function to get available symbols
-func getAvailableSymbols(baseURL string, apiKey string) ([]string, error) {
-	symbolsURL := fmt.Sprintf("%s/availableSymbols", baseURL)
-
-	client := &http.Client{}
-	req, err := http.NewRequest("GET", symbolsURL, nil)
-	if err != nil {
-		return nil, fmt.Errorf("error creating request: %v", err)
-	}
-	req.Header.Set("x-api-key", apiKey)
-
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("error making request: %v", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != 200 {
-		body, _ := ioutil.ReadAll(resp.Body)
-		return nil, fmt.Errorf("request returned status %d: %s", resp.StatusCode, string(body))
-	}
-
-	var symbolsResp SymbolsResponse
-	err = json.NewDecoder(resp.Body).Decode(&symbolsResp)
-	if err != nil {
-		return nil, fmt.Errorf("error decoding JSON: %v", err)
-	}
-
-	return symbolsResp.AvailableSymbols, nil
-}
-
-// Helper function to query stock data
-func queryStockData(baseURL string, apiKey string, symbol string, date string, hour int) error {
-	// Construct the request URL
-	reqURL, err := url.Parse(fmt.Sprintf("%s/getStock", baseURL))
-	if err != nil {
-		return fmt.Errorf("error parsing base URL: %v", err)
-	}
-
-	query := reqURL.Query()
-	query.Set("symbol", symbol)
-	query.Set("date", date)
-	query.Set("hour", fmt.Sprintf("%d", hour))
-	reqURL.RawQuery = query.Encode()
-
-	// Make the GET request
-	client := &http.Client{}
-	req, err := http.NewRequest("GET", reqURL.String(), nil)
-	if err != nil {
-		return fmt.Errorf("error creating request: %v", err)
-	}
-	req.Header.Set("x-api-key", apiKey)
-
-	resp, err := client.Do(req)
-	if err != nil {
-		return fmt.Errorf("error making request: %v", err)
+		batch = append(batch, StockQuery{
+			Symbol: symbol,
+			Date:   randomTime.Format("2006-01-02"),
+			Hour:   randomTime.Hour(),
+		})
 	}
-	defer resp.Body.Close()
 
-	// Read the response body
-	body, err := ioutil.ReadAll(resp.Body)
+	client.RPS = 5
+	results, err := client.QueryBatch(ctx, batch, 4)
 	if err != nil {
-		return fmt.Errorf("error reading response body: %v", err)
-	}
-
-	// Check status code
-	if resp.StatusCode != 200 {
-		var errorResp ErrorResponse
-		err = json.Unmarshal(body, &errorResp)
-		if err != nil {
-			return fmt.Errorf("status %d: %s", resp.StatusCode, string(body))
-		}
-
-		fmt.Printf("Request failed with status %d\n", resp.StatusCode)
-		fmt.Printf("Error message: %s\n", errorResp.Message)
-
-		if len(errorResp.AvailableDates) > 0 {
-			fmt.Printf("Available dates: %v\n", errorResp.AvailableDates)
+		fmt.Printf("Batch query aborted: %v\n", err)
+	}
+
+	for _, result := range results {
+		fmt.Printf("=== %s Query ===\n", result.Query.Symbol)
+		fmt.Printf("Random time: %s %02d:00:00\n", result.Query.Date, result.Query.Hour)
+
+		if result.Err != nil {
+			var apiErr *APIError
+			if errors.As(result.Err, &apiErr) {
+				fmt.Printf("Request failed with status %d\n", apiErr.StatusCode)
+				fmt.Printf("Error message: %s\n", apiErr.Body.Message)
+				if len(apiErr.Body.AvailableDates) > 0 {
+					fmt.Printf("Available dates: %v\n", apiErr.Body.AvailableDates)
+				}
+				if len(apiErr.Body.AvailableHours) > 0 {
+					fmt.Printf("Available hours: %v\n", apiErr.Body.AvailableHours)
+				}
+			} else {
+				fmt.Printf("Error querying stock data: %v\n\n", result.Err)
+			}
+			continue
 		}
 
-		if len(errorResp.AvailableHours) > 0 {
-			fmt.Printf("Available hours: %v\n", errorResp.AvailableHours)
-		}
-
-		return nil
-	}
-
-	// Parse the JSON response
-	var stockResp StockResponse
-	err = json.Unmarshal(body, &stockResp)
-	if err != nil {
-		return fmt.Errorf("error decoding JSON: %v", err)
-	}
-
-	// Print results
-	fmt.Printf("Response Symbol: %s\n", stockResp.Symbol)
-	fmt.Printf("Response Timestamp: %s\n", stockResp.Timestamp)
-	fmt.Printf("Data: %v\n", stockResp.Data)
-
-	return nil
-}
-
-// Helper function to query all data
-func queryAllData(baseURL string, apiKey string) error {
-	allDataURL := fmt.Sprintf("%s/allData", baseURL)
-
-	client := &http.Client{}
-	req, err := http.NewRequest("GET", allDataURL, nil)
-	if err != nil {
-		return fmt.Errorf("error creating request: %v", err)
-	}
-	req.Header.Set("x-api-key", apiKey)
-
-	resp, err := client.Do(req)
-	if err != nil {
-		return fmt.Errorf("error making request: %v", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != 200 {
-		body, _ := ioutil.ReadAll(resp.Body)
-		return fmt.Errorf("request returned status %d: %s", resp.StatusCode, string(body))
+		fmt.Printf("Response Symbol: %s\n", result.Response.Symbol)
+		fmt.Printf("Response Timestamp: %s\n", result.Response.Timestamp)
+		fmt.Printf("Data: %v\n", result.Response.Data)
 	}
 
-	// Read the response but don't attempt to parse the whole thing
-	// as it could be very large
-	body, err := ioutil.ReadAll(resp.Body)
+	// 5) Also test the /allData endpoint, streaming it symbol by symbol
+	// instead of buffering the whole response.
+	fmt.Println("\n=== Testing /allData endpoint ===")
+	err = client.StreamAllData(ctx, func(symbol string, points map[string]string) error {
+		fmt.Printf("Symbol %s: %d data points\n", symbol, len(points))
+		return nil
+	})
 	if err != nil {
-		return fmt.Errorf("error reading response body: %v", err)
+		fmt.Printf("Error streaming all data: %v\n", err)
 	}
 
-	// Check if it's an error message with special "message" field
-	var errorCheck map[string]interface{}
-	err = json.Unmarshal(body, &errorCheck)
+	// 6) Also test the /getStockRange endpoint for pulling a backfill in one
+	// call instead of hammering /getStock per hour.
+	fmt.Println("\n=== Testing /getStockRange endpoint ===")
+	rangeEnd := time.Now().UTC()
+	rangeStart := rangeEnd.Add(-6 * time.Hour)
+	rangeResp, err := client.QueryStockRange(ctx, fangSymbols[0], rangeStart.Format(time.RFC3339), rangeEnd.Format(time.RFC3339), "1h")
 	if err != nil {
-		return fmt.Errorf("error parsing JSON response: %v", err)
-	}
-
-	if message, ok := errorCheck["message"]; ok {
-		fmt.Printf("Received message instead of data: %v\n", message)
-		if reason, ok := errorCheck["reason"]; ok {
-			fmt.Printf("Reason: %v\n", reason)
-		}
-		return nil
-	}
-
-	// Just count how many symbols and data points we got
-	symbolCount := len(errorCheck)
-	totalDataPoints := 0
-
-	for symbol, data := range errorCheck {
-		if dataMap, ok := data.(map[string]interface{}); ok {
-			dataPoints := len(dataMap)
-			totalDataPoints += dataPoints
-			fmt.Printf("Symbol %s: %d data points\n", symbol, dataPoints)
+		var apiErr *APIError
+		if errors.As(err, &apiErr) {
+			fmt.Printf("Request failed with status %d\n", apiErr.StatusCode)
+			fmt.Printf("Error message: %s\n", apiErr.Body.Message)
+			if len(apiErr.Body.AvailableDates) > 0 {
+				fmt.Printf("Available dates: %v\n", apiErr.Body.AvailableDates)
+			}
+			if len(apiErr.Body.AvailableHours) > 0 {
+				fmt.Printf("Available hours: %v\n", apiErr.Body.AvailableHours)
+			}
+		} else {
+			fmt.Printf("Error querying stock range: %v\n", err)
 		}
+	} else {
+		fmt.Printf("Got %d samples for %s\n", len(rangeResp.Samples), rangeResp.Symbol)
 	}
 
-	fmt.Printf("Total: %d symbols, %d data points\n", symbolCount, totalDataPoints)
-
-	return nil
+	fmt.Println("\nAll queries complete.")
 }